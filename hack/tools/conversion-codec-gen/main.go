@@ -0,0 +1,220 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// conversion-codec-gen scans a package for struct types and emits a .proto schema for a
+// protobuf-backed util/conversion.Codec, so provider repos don't have to hand-author one.
+//
+// It is AST-only (it does not run the type checker), so it cannot tell which structs in the
+// package actually implement conversion.Convertible: it emits a message for every exported struct
+// type it finds, which is usually a superset of the spoke types a caller cares about. Narrow the
+// input with --package to a directory that only contains spoke types, or hand-edit the output.
+//
+// Field support is intentionally limited to the kinds that already show up in cluster-api spoke
+// types: primitives, pointers, named types, types from other packages, slices of any supported
+// element, and string-keyed maps of any supported value. A named type is emitted as a reference to
+// a message of the same name (so a named string/int type yields a reference with no matching
+// message), and a type from another package is emitted as opaque bytes. Any field outside that set (channels,
+// funcs, interfaces, non-string-keyed maps, ...) is a hard error for the whole run rather than a
+// silently-wrong schema, since a dropped field here would mean silent data loss in the codec built
+// from it.
+//
+// The emitted .proto still needs `protoc` (with the usual k8s protobuf plugins) to produce the
+// Go (`.pb.go`) bindings; that step is left to `make generate`, the same as zz_generated.pb.go is
+// produced for the API types themselves. Run it with:
+//
+//	go run ./hack/tools/conversion-codec-gen --package ./api/v1alpha3 --output ./api/v1alpha3
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	pkgDir := flag.String("package", "", "directory of the spoke package to scan for struct types")
+	outDir := flag.String("output", "", "directory to write the generated .proto file to")
+	flag.Parse()
+
+	if *pkgDir == "" || *outDir == "" {
+		fmt.Fprintln(os.Stderr, "both --package and --output are required")
+		os.Exit(1)
+	}
+
+	if err := run(*pkgDir, *outDir); err != nil {
+		fmt.Fprintf(os.Stderr, "conversion-codec-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(pkgDir, outDir string) error {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, pkgDir, nil, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", pkgDir, err)
+	}
+
+	var messages []protoMessage
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				ts, ok := n.(*ast.TypeSpec)
+				if !ok {
+					return true
+				}
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || !ts.Name.IsExported() {
+					return true
+				}
+				messages = append(messages, protoMessage{name: ts.Name.Name, fields: st.Fields.List})
+				return true
+			})
+		}
+	}
+
+	schema, err := renderProtoSchema(messages)
+	if err != nil {
+		return err
+	}
+
+	protoPath := filepath.Join(outDir, "conversion_generated.proto")
+	if err := os.WriteFile(protoPath, []byte(schema), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", protoPath, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "wrote %s; run protoc (see hack/tools/conversion-codec-gen doc comment) to produce the .pb.go bindings\n", protoPath)
+	return nil
+}
+
+type protoMessage struct {
+	name   string
+	fields []*ast.Field
+}
+
+// renderProtoSchema maps every message's fields to proto field declarations, returning an error
+// (and emitting nothing) the first time it hits a field type it doesn't know how to represent.
+func renderProtoSchema(messages []protoMessage) (string, error) {
+	var out strings.Builder
+	fmt.Fprintln(&out, "// Code generated by conversion-codec-gen. DO NOT EDIT.")
+	fmt.Fprintln(&out, `syntax = "proto2";`)
+	fmt.Fprintln(&out)
+
+	for _, m := range messages {
+		fmt.Fprintf(&out, "message %s {\n", m.name)
+
+		fieldNum := 1
+		for _, field := range m.fields {
+			names := field.Names
+			if len(names) == 0 {
+				// Embedded field: proto has no equivalent, so emit it as a single field named
+				// after the embedded type instead of silently dropping it.
+				names = []*ast.Ident{{Name: exprString(field.Type)}}
+			}
+
+			protoType, err := protoFieldType(field.Type)
+			if err != nil {
+				return "", fmt.Errorf("message %s: %w", m.name, err)
+			}
+
+			// Repeated and map fields take no "optional" label in proto2.
+			label := "optional "
+			if strings.HasPrefix(protoType, "repeated ") || strings.HasPrefix(protoType, "map<") {
+				label = ""
+			}
+
+			for _, name := range names {
+				fmt.Fprintf(&out, "  %s%s %s = %d;\n", label, protoType, name.Name, fieldNum)
+				fieldNum++
+			}
+		}
+
+		fmt.Fprintln(&out, "}")
+		fmt.Fprintln(&out)
+	}
+
+	return out.String(), nil
+}
+
+// protoFieldType maps a Go AST field type to a proto scalar/message type, mirroring the field
+// kinds cluster-api spoke types actually use. It errors on anything else instead of guessing.
+func protoFieldType(expr ast.Expr) (string, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return "string", nil
+		case "bool":
+			return "bool", nil
+		case "int", "int32", "int64":
+			return "int64", nil
+		case "uint", "uint32", "uint64":
+			return "uint64", nil
+		case "float32", "float64":
+			return "double", nil
+		default:
+			// A named type (either a local struct or a named primitive, e.g. a string-based enum)
+			// is represented as a nested message/string; without the type checker we can't tell
+			// which, so we conservatively emit it as a message reference sharing the type's name.
+			return t.Name, nil
+		}
+	case *ast.StarExpr:
+		return protoFieldType(t.X)
+	case *ast.SelectorExpr:
+		// A qualified type from another package (metav1.Time, etc). We can't resolve its shape
+		// without the type checker, so represent it opaquely as bytes rather than guessing wrong.
+		return "bytes", nil
+	case *ast.ArrayType:
+		elem, err := protoFieldType(t.Elt)
+		if err != nil {
+			return "", err
+		}
+		return "repeated " + elem, nil
+	case *ast.MapType:
+		key, ok := t.Key.(*ast.Ident)
+		if !ok || key.Name != "string" {
+			return "", fmt.Errorf("unsupported non-string map key type %s", exprString(t.Key))
+		}
+		val, err := protoFieldType(t.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("map<string, %s>", val), nil
+	default:
+		return "", fmt.Errorf("unsupported field type %s", exprString(expr))
+	}
+}
+
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.ArrayType:
+		return "[]" + exprString(t.Elt)
+	case *ast.MapType:
+		return fmt.Sprintf("map[%s]%s", exprString(t.Key), exprString(t.Value))
+	default:
+		return fmt.Sprintf("%T", expr)
+	}
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// EncodingAnnotation records which Codec was used to produce the data stored under
+// DataAnnotation, so UnmarshalDataWithCodec knows how to decode it. Its absence means JSON, for
+// backwards compatibility with objects converted before codecs existed.
+const EncodingAnnotation = "cluster.x-k8s.io/conversion-data-encoding"
+
+// Codec encodes and decodes the payload MarshalDataWithCodec/UnmarshalDataWithCodec store under
+// DataAnnotation. Encode returns the encoded bytes and the encoding identifier to record in
+// EncodingAnnotation.
+type Codec interface {
+	// Encode serializes obj, returning the encoded bytes and this codec's encoding identifier.
+	Encode(obj runtime.Object) ([]byte, string, error)
+	// Decode deserializes data, previously produced by Encode under the given encoding, into into.
+	// encoding is always this codec's own identifier; it's passed through so a single Codec value
+	// can serve more than one related encoding (e.g. multiple protobuf schema versions).
+	Decode(data []byte, encoding string, into interface{}) error
+}
+
+// Well-known encoding identifiers stored in EncodingAnnotation.
+const (
+	JSONEncoding     = "json"
+	GzipJSONEncoding = "gzip+json"
+	ProtobufEncoding = "protobuf"
+)
+
+var codecs = map[string]Codec{
+	JSONEncoding:     jsonCodec{},
+	GzipJSONEncoding: gzipJSONCodec{},
+	ProtobufEncoding: protobufCodec{},
+}
+
+// RegisterCodec makes a Codec available to MarshalDataWithCodec/UnmarshalDataWithCodec under
+// encoding. Provider repos call this from an init function to override ProtobufEncoding with a
+// codec built around a generated per-spoke-type schema (narrower, and smaller on the wire, than
+// the generic envelope protobufCodec falls back to); see hack/tools/conversion-codec-gen.
+func RegisterCodec(encoding string, codec Codec) {
+	codecs[encoding] = codec
+}
+
+// MarshalDataWithCodec behaves like MarshalData but encodes src with the named codec instead of
+// hard-coding JSON, recording the choice in EncodingAnnotation so UnmarshalDataWithCodec can
+// decode it symmetrically. Passing JSONEncoding stores the same payload MarshalData does, plus
+// EncodingAnnotation set to JSONEncoding.
+func MarshalDataWithCodec(src metav1.Object, dst metav1.Object, encoding string) error {
+	codec, ok := codecs[encoding]
+	if !ok {
+		return errors.Errorf("no conversion codec registered for encoding %q", encoding)
+	}
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(src)
+	if err != nil {
+		return err
+	}
+	delete(u, "metadata")
+
+	data, usedEncoding, err := codec.Encode(&unstructured.Unstructured{Object: u})
+	if err != nil {
+		return err
+	}
+
+	if dst.GetAnnotations() == nil {
+		dst.SetAnnotations(map[string]string{})
+	}
+	dst.GetAnnotations()[DataAnnotation] = string(data)
+	dst.GetAnnotations()[EncodingAnnotation] = usedEncoding
+	return nil
+}
+
+// UnmarshalDataWithCodec is the counterpart to MarshalDataWithCodec: it reads EncodingAnnotation
+// (defaulting to JSONEncoding when absent) to select the codec used to decode the data stored
+// under DataAnnotation into to.
+func UnmarshalDataWithCodec(from metav1.Object, to interface{}) (bool, error) {
+	data, ok := from.GetAnnotations()[DataAnnotation]
+	if !ok {
+		return false, nil
+	}
+
+	encoding := from.GetAnnotations()[EncodingAnnotation]
+	if encoding == "" {
+		encoding = JSONEncoding
+	}
+	codec, ok := codecs[encoding]
+	if !ok {
+		return false, errors.Errorf("no conversion codec registered for encoding %q", encoding)
+	}
+
+	if err := codec.Decode([]byte(data), encoding, to); err != nil {
+		return false, err
+	}
+
+	delete(from.GetAnnotations(), DataAnnotation)
+	delete(from.GetAnnotations(), EncodingAnnotation)
+	return true, nil
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(obj runtime.Object) ([]byte, string, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, "", errors.Errorf("jsonCodec.Encode expects *unstructured.Unstructured, got %T", obj)
+	}
+	data, err := json.Marshal(u.Object)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, JSONEncoding, nil
+}
+
+func (jsonCodec) Decode(data []byte, _ string, into interface{}) error {
+	return json.Unmarshal(data, into)
+}
+
+type gzipJSONCodec struct{}
+
+func (gzipJSONCodec) Encode(obj runtime.Object) ([]byte, string, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, "", errors.Errorf("gzipJSONCodec.Encode expects *unstructured.Unstructured, got %T", obj)
+	}
+	raw, err := json.Marshal(u.Object)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(raw); err != nil {
+		return nil, "", err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, "", err
+	}
+
+	// Annotation values are stored as JSON strings and must be valid UTF-8; gzip output is
+	// arbitrary binary, so it's base64-encoded the same way Helm encodes its release annotations.
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(buf.Len()))
+	base64.StdEncoding.Encode(encoded, buf.Bytes())
+	return encoded, GzipJSONEncoding, nil
+}
+
+func (gzipJSONCodec) Decode(data []byte, _ string, into interface{}) error {
+	compressed := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+	n, err := base64.StdEncoding.Decode(compressed, data)
+	if err != nil {
+		return err
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(compressed[:n]))
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	raw, err := io.ReadAll(gr)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, into)
+}
+
+// protobufMagic is the 4-byte prefix the Kubernetes protobuf wire format uses ahead of the
+// serialized runtime.Unknown envelope; see k8s.io/apimachinery/pkg/runtime/serializer/protobuf.
+var protobufMagic = []byte{0x6b, 0x38, 0x73, 0x00} // "k8s\x00"
+
+// protobufCodec is the default ProtobufEncoding implementation. It has no compiled-in schema for
+// spoke types, so it can't shrink the payload the way a per-type generated message would; instead
+// it wraps the JSON bytes in the same generic runtime.Unknown envelope the API server itself uses
+// to serve protobuf for types without a registered proto message (e.g. CRDs). This keeps
+// ProtobufEncoding usable out of the box and wire-compatible with genuine k8s protobuf tooling.
+// Provider repos that want an actual size reduction should generate a narrower schema with
+// hack/tools/conversion-codec-gen and RegisterCodec(ProtobufEncoding, ...) to override this.
+type protobufCodec struct{}
+
+func (protobufCodec) Encode(obj runtime.Object) ([]byte, string, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, "", errors.Errorf("protobufCodec.Encode expects *unstructured.Unstructured, got %T", obj)
+	}
+	raw, err := json.Marshal(u.Object)
+	if err != nil {
+		return nil, "", err
+	}
+
+	unk := runtime.Unknown{Raw: raw, ContentType: runtime.ContentTypeJSON}
+	body, err := unk.Marshal()
+	if err != nil {
+		return nil, "", err
+	}
+
+	wire := append(append([]byte{}, protobufMagic...), body...)
+
+	// As with gzipJSONCodec, the wire format is arbitrary binary and annotation values must be
+	// valid UTF-8, so it's base64-encoded rather than stored raw.
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(wire)))
+	base64.StdEncoding.Encode(encoded, wire)
+	return encoded, ProtobufEncoding, nil
+}
+
+func (protobufCodec) Decode(data []byte, _ string, into interface{}) error {
+	wire := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+	n, err := base64.StdEncoding.Decode(wire, data)
+	if err != nil {
+		return err
+	}
+	wire = wire[:n]
+
+	if len(wire) < len(protobufMagic) || !bytes.Equal(wire[:len(protobufMagic)], protobufMagic) {
+		return errors.Errorf("protobufCodec.Decode: data is missing the %x protobuf magic prefix", protobufMagic)
+	}
+
+	var unk runtime.Unknown
+	if err := unk.Unmarshal(wire[len(protobufMagic):]); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(unk.Raw, into)
+}
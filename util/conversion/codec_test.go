@@ -0,0 +1,62 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCodecsRoundTripAndProduceValidUTF8(t *testing.T) {
+	// Plain ASCII input is enough to catch unencoded output: gzip and the protobuf/runtime.Unknown
+	// envelope both produce arbitrary binary on the wire regardless of how innocuous the source
+	// JSON is, and that binary is what Encode's caller stores verbatim in a map[string]string
+	// annotation, which Kubernetes round-trips through JSON.
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"foo": "bar",
+			"baz": []interface{}{"some value", "another value"},
+		},
+	}}
+
+	tests := []struct {
+		name  string
+		codec Codec
+	}{
+		{name: JSONEncoding, codec: jsonCodec{}},
+		{name: GzipJSONEncoding, codec: gzipJSONCodec{}},
+		{name: ProtobufEncoding, codec: protobufCodec{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			data, encoding, err := tt.codec.Encode(obj)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(encoding).To(Equal(tt.name))
+			g.Expect(utf8.Valid(data)).To(BeTrue(), "encoded annotation payload must be valid UTF-8")
+
+			var got map[string]interface{}
+			g.Expect(tt.codec.Decode(data, encoding, &got)).To(Succeed())
+			g.Expect(got).To(Equal(obj.Object))
+		})
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrContractNotSatisfied is returned by ConvertReferenceToContract when the target CRD exists
+// but does not advertise support for the requested contract version, as opposed to the CRD not
+// existing at all (which surfaces the underlying apierrors.IsNotFound error instead).
+type ErrContractNotSatisfied struct {
+	GroupKind      string
+	TargetContract string
+}
+
+func (e *ErrContractNotSatisfied) Error() string {
+	return fmt.Sprintf("CRD for %s does not support contract version %q", e.GroupKind, e.TargetContract)
+}
+
+// ConvertReferenceToContract takes a client and object reference, queries the API Server for the
+// Custom Resource Definition and rewrites ref to the newest version satisfying targetContract,
+// instead of always jumping to the CRD's overall newest version. This matters for CRDs that
+// advertise support for more than one contract simultaneously (e.g. v1alpha3 and v1alpha4): a
+// caller built against the older contract must keep being routed to a version that still
+// satisfies it, rather than being force-upgraded to the hub.
+//
+// The object passed as input is modified in place if a compatible version is found.
+func ConvertReferenceToContract(ctx context.Context, c client.Client, restConfig *rest.Config, ref *corev1.ObjectReference, targetContract string) error {
+	gvk := ref.GroupVersionKind()
+	// Contract version labels are always keyed on the core cluster-api group, regardless of which
+	// group the referenced CRD itself belongs to: a provider CRD (infrastructure.cluster.x-k8s.io,
+	// bootstrap.cluster.x-k8s.io, ...) still advertises e.g. "cluster.x-k8s.io/v1alpha3".
+	contractLabel := fmt.Sprintf("%s/%s", clusterv1.GroupVersion.Group, targetContract)
+
+	metadata, err := getCRDMetadata(ctx, c, restConfig, gvk, contractLabel)
+	if err != nil {
+		return err
+	}
+
+	supportedVersions, ok := metadata.Labels[contractLabel]
+	if !ok || supportedVersions == "" {
+		return &ErrContractNotSatisfied{GroupKind: gvk.GroupKind().String(), TargetContract: targetContract}
+	}
+
+	chosen, err := highestKubeAwareVersion(supportedVersions)
+	if err != nil {
+		return err
+	}
+
+	if gvk.Version != chosen {
+		gvk.Version = chosen
+		ref.SetGroupVersionKind(gvk)
+	}
+
+	return nil
+}
+
+// NegotiateContract picks the highest version present in both supported and offered, using the
+// same kube-aware ordering (v1alpha < v1beta < v1) as the contract version labels themselves.
+// It's meant for callers that, unlike ConvertReferenceToContract, don't want to hard-code which
+// contract to ask for and instead want to pick the best one two components can agree on.
+func NegotiateContract(supported []string, offered []string) (string, error) {
+	offeredSet := make(map[string]bool, len(offered))
+	for _, o := range offered {
+		offeredSet[o] = true
+	}
+
+	var mutual util.KubeAwareAPIVersions
+	for _, s := range supported {
+		if offeredSet[s] {
+			mutual = append(mutual, s)
+		}
+	}
+
+	if len(mutual) == 0 {
+		return "", errors.Errorf("no mutually supported contract version found between %v and %v", supported, offered)
+	}
+
+	sort.Sort(mutual)
+	return mutual[len(mutual)-1], nil
+}
@@ -21,18 +21,15 @@ import (
 	"math/rand"
 	"sort"
 	"strings"
-	"testing"
 
-	"github.com/google/go-cmp/cmp"
 	fuzz "github.com/google/gofuzz"
-	"github.com/onsi/gomega"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
-	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/util/json"
 	"k8s.io/client-go/rest"
@@ -40,7 +37,6 @@ import (
 	"sigs.k8s.io/cluster-api/util"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 const (
@@ -56,21 +52,11 @@ var (
 //
 // The object passed as input is modified in place if an updated compatible version is found.
 func ConvertReferenceAPIContract(ctx context.Context, c client.Client, restConfig *rest.Config, ref *corev1.ObjectReference) error {
-	log := ctrl.LoggerFrom(ctx)
 	gvk := ref.GroupVersionKind()
 
-	metadata, err := util.GetCRDMetadataFromGVK(ctx, restConfig, gvk)
+	metadata, err := getCRDMetadata(ctx, c, restConfig, gvk, contract)
 	if err != nil {
-		log.Info("Cannot retrieve CRD with metadata only client, falling back to slower listing", "err", err.Error())
-		// Fallback to slower and more memory intensive method to get the full CRD.
-		crd, err := util.GetCRDWithContract(ctx, c, gvk, contract)
-		if err != nil {
-			return err
-		}
-		metadata = &metav1.PartialObjectMetadata{
-			TypeMeta:   crd.TypeMeta,
-			ObjectMeta: crd.ObjectMeta,
-		}
+		return err
 	}
 
 	// If there is no label, return early without changing the reference.
@@ -79,10 +65,10 @@ func ConvertReferenceAPIContract(ctx context.Context, c client.Client, restConfi
 		return errors.Errorf("cannot find any versions matching contract %q for CRD %v as contract version label(s) are either missing or empty", contract, metadata.Name)
 	}
 
-	// Pick the latest version in the slice and validate it.
-	kubeVersions := util.KubeAwareAPIVersions(strings.Split(supportedVersions, "_"))
-	sort.Sort(kubeVersions)
-	chosen := kubeVersions[len(kubeVersions)-1]
+	chosen, err := highestKubeAwareVersion(supportedVersions)
+	if err != nil {
+		return err
+	}
 
 	// Modify the GroupVersionKind with the new version.
 	if gvk.Version != chosen {
@@ -93,6 +79,41 @@ func ConvertReferenceAPIContract(ctx context.Context, c client.Client, restConfi
 	return nil
 }
 
+// getCRDMetadata retrieves the CRD backing gvk, preferring the metadata-only client and falling
+// back to the slower, more memory intensive full listing filtered by contractLabel. It's shared
+// by ConvertReferenceAPIContract and ConvertReferenceToContract, which only differ in how they
+// interpret the contract version label(s) on the returned metadata.
+func getCRDMetadata(ctx context.Context, c client.Client, restConfig *rest.Config, gvk schema.GroupVersionKind, contractLabel string) (*metav1.PartialObjectMetadata, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	metadata, err := util.GetCRDMetadataFromGVK(ctx, restConfig, gvk)
+	if err != nil {
+		log.Info("Cannot retrieve CRD with metadata only client, falling back to slower listing", "err", err.Error())
+		// Fallback to slower and more memory intensive method to get the full CRD.
+		crd, err := util.GetCRDWithContract(ctx, c, gvk, contractLabel)
+		if err != nil {
+			return nil, err
+		}
+		metadata = &metav1.PartialObjectMetadata{
+			TypeMeta:   crd.TypeMeta,
+			ObjectMeta: crd.ObjectMeta,
+		}
+	}
+
+	return metadata, nil
+}
+
+// highestKubeAwareVersion picks the newest version out of an underscore-separated list, using the
+// same kube-aware ordering (v1alpha < v1beta < v1) the contract version labels are defined with.
+func highestKubeAwareVersion(versions string) (string, error) {
+	kubeVersions := util.KubeAwareAPIVersions(strings.Split(versions, "_"))
+	if len(kubeVersions) == 0 {
+		return "", errors.Errorf("no versions found in %q", versions)
+	}
+	sort.Sort(kubeVersions)
+	return kubeVersions[len(kubeVersions)-1], nil
+}
+
 // MarshalData stores the source object as json data in the destination object annotations map.
 // It ignores the metadata of the source object.
 func MarshalData(src metav1.Object, dst metav1.Object) error {
@@ -135,54 +156,3 @@ func GetFuzzer(scheme *runtime.Scheme, funcs ...fuzzer.FuzzerFuncs) *fuzz.Fuzzer
 		serializer.NewCodecFactory(scheme),
 	)
 }
-
-// FuzzTestFunc returns a new testing function to be used in tests to make sure conversions between
-// the Hub version of an object and an older version aren't lossy.
-func FuzzTestFunc(scheme *runtime.Scheme, hub conversion.Hub, dst conversion.Convertible, funcs ...fuzzer.FuzzerFuncs) func(*testing.T) {
-	return func(t *testing.T) {
-		t.Run("spoke-hub-spoke", func(t *testing.T) {
-			g := gomega.NewWithT(t)
-			fuzzer := GetFuzzer(scheme, funcs...)
-
-			for i := 0; i < 10000; i++ {
-				// Create the spoke and fuzz it
-				spokeBefore := dst.DeepCopyObject().(conversion.Convertible)
-				fuzzer.Fuzz(spokeBefore)
-
-				// First convert spoke to hub
-				hubCopy := hub.DeepCopyObject().(conversion.Hub)
-				g.Expect(spokeBefore.ConvertTo(hubCopy)).To(gomega.Succeed())
-
-				// Convert hub back to spoke and check if the resulting spoke is equal to the spoke before the round trip
-				spokeAfter := dst.DeepCopyObject().(conversion.Convertible)
-				g.Expect(spokeAfter.ConvertFrom(hubCopy)).To(gomega.Succeed())
-
-				// Remove data annotation eventually added by ConvertFrom for avoiding data loss in hub-spoke-hub round trips
-				metaAfter := spokeAfter.(metav1.Object)
-				delete(metaAfter.GetAnnotations(), DataAnnotation)
-
-				g.Expect(apiequality.Semantic.DeepEqual(spokeBefore, spokeAfter)).To(gomega.BeTrue(), cmp.Diff(spokeBefore, spokeAfter))
-			}
-		})
-		t.Run("hub-spoke-hub", func(t *testing.T) {
-			g := gomega.NewWithT(t)
-			fuzzer := GetFuzzer(scheme, funcs...)
-
-			for i := 0; i < 10000; i++ {
-				// Create the hub and fuzz it
-				hubBefore := hub.DeepCopyObject().(conversion.Hub)
-				fuzzer.Fuzz(hubBefore)
-
-				// First convert hub to spoke
-				dstCopy := dst.DeepCopyObject().(conversion.Convertible)
-				g.Expect(dstCopy.ConvertFrom(hubBefore)).To(gomega.Succeed())
-
-				// Convert spoke back to hub and check if the resulting hub is equal to the hub before the round trip
-				hubAfter := hub.DeepCopyObject().(conversion.Hub)
-				g.Expect(dstCopy.ConvertTo(hubAfter)).To(gomega.Succeed())
-
-				g.Expect(apiequality.Semantic.DeepEqual(hubBefore, hubAfter)).To(gomega.BeTrue(), cmp.Diff(hubBefore, hubAfter))
-			}
-		})
-	}
-}
@@ -0,0 +1,273 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// DeltaDataAnnotation stores the payload MarshalDelta/UnmarshalDelta produce and consume. It is
+// deliberately distinct from DataAnnotation (a JSON object produced by MarshalData/
+// MarshalDataWithCodec): MarshalDelta's payload is a JSON array, and reusing DataAnnotation would
+// let a caller pair the wrong Marshal/Unmarshal pair (e.g. UnmarshalData against a delta-encoded
+// object) and silently mis-decode instead of erroring.
+const DeltaDataAnnotation = "cluster.x-k8s.io/conversion-delta-data"
+
+// maxDeltaAnnotationSize is the soft limit Kubernetes enforces on a single annotation value.
+// We stay comfortably under the etcd object size limit callers actually care about.
+const maxDeltaAnnotationSize = 256 * 1024
+
+// pathValue is a single JSON-path/value pair captured by MarshalDelta.
+type pathValue struct {
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalDelta stores, under DeltaDataAnnotation, only the leaf JSON paths of src that do not
+// survive a src -> hubShape -> src round trip, instead of the full src payload. hubShape must be a
+// zero-value (or representative) instance of the hub type so MarshalDelta can detect which fields
+// the hub actually preserves. It ignores the metadata of the source object, mirroring MarshalData.
+func MarshalDelta(src metav1.Object, dst metav1.Object, hubShape interface{}) error {
+	srcMap, err := toUnstructuredMap(src)
+	if err != nil {
+		return err
+	}
+	delete(srcMap, "metadata")
+
+	hubMap, err := roundTripThroughHub(srcMap, hubShape)
+	if err != nil {
+		return err
+	}
+
+	var deltas []pathValue
+	walkLeaves(srcMap, nil, func(path []string, value interface{}) {
+		if !reflect.DeepEqual(value, lookupPath(hubMap, path)) {
+			deltas = append(deltas, pathValue{Path: encodePath(path), Value: value})
+		}
+	})
+
+	data, err := json.Marshal(deltas)
+	if err != nil {
+		return err
+	}
+	if len(data) > maxDeltaAnnotationSize {
+		return errors.Errorf("conversion delta for %T is %d bytes, exceeding the %d byte annotation limit; caller must fall back to MarshalData", src, len(data), maxDeltaAnnotationSize)
+	}
+
+	if dst.GetAnnotations() == nil {
+		dst.SetAnnotations(map[string]string{})
+	}
+	dst.GetAnnotations()[DeltaDataAnnotation] = string(data)
+	return nil
+}
+
+// UnmarshalDelta retrieves the path/value pairs stored by MarshalDelta from the annotation on
+// from, and applies them onto to, which is expected to already hold the result of the regular
+// hub<->spoke conversion. It returns false if no delta annotation was found.
+func UnmarshalDelta(from metav1.Object, to interface{}) (bool, error) {
+	data, ok := from.GetAnnotations()[DeltaDataAnnotation]
+	if !ok {
+		return false, nil
+	}
+
+	var deltas []pathValue
+	if err := json.Unmarshal([]byte(data), &deltas); err != nil {
+		return false, err
+	}
+
+	toMap, err := toUnstructuredMap(to)
+	if err != nil {
+		return false, err
+	}
+
+	for _, d := range deltas {
+		setPath(toMap, decodePath(d.Path), d.Value)
+	}
+
+	out, err := json.Marshal(toMap)
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(out, to); err != nil {
+		return false, err
+	}
+
+	delete(from.GetAnnotations(), DeltaDataAnnotation)
+	return true, nil
+}
+
+func toUnstructuredMap(obj interface{}) (map[string]interface{}, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// roundTripThroughHub marshals srcMap into hubShape and back into a map, simulating the lossy
+// conversion a real spoke->hub->spoke trip would perform.
+func roundTripThroughHub(srcMap map[string]interface{}, hubShape interface{}) (map[string]interface{}, error) {
+	hubType := reflect.TypeOf(hubShape)
+	if hubType == nil || hubType.Kind() != reflect.Ptr {
+		return nil, errors.Errorf("roundTripThroughHub: hubShape must be a non-nil pointer to the hub type, got %T", hubShape)
+	}
+
+	data, err := json.Marshal(srcMap)
+	if err != nil {
+		return nil, err
+	}
+
+	hub := reflect.New(hubType.Elem()).Interface()
+	// Best-effort: fields that don't exist on the hub are simply dropped by json.Unmarshal,
+	// which is exactly the lossiness we need to detect.
+	_ = json.Unmarshal(data, hub)
+
+	return toUnstructuredMap(hub)
+}
+
+// walkLeaves visits every leaf value (i.e. not a map or slice, or an empty map or slice) in obj,
+// calling fn with the path of map keys/slice indices leading to it. An empty map or slice is
+// treated as a leaf in its own right rather than simply not recursing into it, so that MarshalDelta
+// can still detect and preserve the case where the hub round trip drops an originally-empty
+// collection entirely (e.g. turns it into a nil field).
+func walkLeaves(obj interface{}, path []string, fn func(path []string, value interface{})) {
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			fn(path, v)
+			return
+		}
+		for k, val := range v {
+			walkLeaves(val, append(append([]string{}, path...), escapeKey(k)), fn)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			fn(path, v)
+			return
+		}
+		for i, val := range v {
+			walkLeaves(val, append(append([]string{}, path...), strconv.Itoa(i)), fn)
+		}
+	default:
+		fn(path, v)
+	}
+}
+
+func lookupPath(obj interface{}, path []string) interface{} {
+	cur := obj
+	for _, segment := range path {
+		key := unescapeKey(segment)
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			val, ok := v[key]
+			if !ok {
+				return nil
+			}
+			cur = val
+		case []interface{}:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil
+			}
+			cur = v[idx]
+		default:
+			return nil
+		}
+	}
+	return cur
+}
+
+// setPath sets value at path within obj, creating and growing intermediate maps and slices as
+// needed. In particular, a slice the base conversion shortened or dropped entirely is grown/
+// reconstructed to fit path rather than silently no-op'ing, since recovering exactly that kind of
+// loss is what MarshalDelta/UnmarshalDelta exist for.
+func setPath(obj map[string]interface{}, path []string, value interface{}) {
+	if len(path) == 0 {
+		return
+	}
+	key := unescapeKey(path[0])
+	if len(path) == 1 {
+		obj[key] = value
+		return
+	}
+	obj[key] = setIn(obj[key], path[1:], value)
+}
+
+// setIn sets value at the remaining path segments within container, which holds whatever
+// UnmarshalDelta's target currently has at this point in the path (a map, a slice, something of
+// the wrong shape, or nothing at all), and returns the container to write back into its parent.
+// Which kind of container to (re)build is decided by the next path segment: walkLeaves always
+// emits a plain decimal index for slice elements, so a segment that parses as one means "this
+// level is a slice", not "this level is a map with a numeric-looking key".
+func setIn(container interface{}, path []string, value interface{}) interface{} {
+	if idx, err := strconv.Atoi(path[0]); err == nil && idx >= 0 {
+		slice, _ := container.([]interface{})
+		for len(slice) <= idx {
+			slice = append(slice, nil)
+		}
+		if len(path) == 1 {
+			slice[idx] = value
+		} else {
+			slice[idx] = setIn(slice[idx], path[1:], value)
+		}
+		return slice
+	}
+
+	m, ok := container.(map[string]interface{})
+	if !ok {
+		m = map[string]interface{}{}
+	}
+	key := unescapeKey(path[0])
+	if len(path) == 1 {
+		m[key] = value
+	} else {
+		m[key] = setIn(m[key], path[1:], value)
+	}
+	return m
+}
+
+// encodePath joins an already-escaped set of path segments into the sjson-style dotted path
+// stored in the annotation.
+func encodePath(path []string) string {
+	return strings.Join(path, ".")
+}
+
+func decodePath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+// escapeKey escapes '.', '[' and '~' in a map key so it can be safely joined into a dotted path
+// without being confused for a path separator or array index marker.
+func escapeKey(key string) string {
+	replacer := strings.NewReplacer("~", "~0", ".", "~1", "[", "~2")
+	return replacer.Replace(key)
+}
+
+func unescapeKey(key string) string {
+	replacer := strings.NewReplacer("~2", "[", "~1", ".", "~0", "~")
+	return replacer.Replace(key)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestEscapeKeyRoundTrip(t *testing.T) {
+	tests := []string{
+		"plain",
+		"has.dot",
+		"has[bracket",
+		"has~tilde",
+		"has.all[three~combined",
+		"",
+	}
+
+	for _, key := range tests {
+		t.Run(key, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(unescapeKey(escapeKey(key))).To(Equal(key))
+		})
+	}
+}
+
+func TestSetPathGrowsAndReconstructsSlices(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  map[string]interface{}
+		path []string
+		want map[string]interface{}
+	}{
+		{
+			name: "slice entirely missing is reconstructed",
+			obj:  map[string]interface{}{},
+			path: []string{"spec", "foo", "0", "bar"},
+			want: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"foo": []interface{}{
+						map[string]interface{}{"bar": "value"},
+					},
+				},
+			},
+		},
+		{
+			name: "slice shortened by the hub is grown to fit",
+			obj: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"foo": []interface{}{"kept"},
+				},
+			},
+			path: []string{"spec", "foo", "2"},
+			want: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"foo": []interface{}{"kept", nil, "value"},
+				},
+			},
+		},
+		{
+			name: "wrong-shaped intermediate (map where a slice is needed) is replaced",
+			obj: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"foo": map[string]interface{}{},
+				},
+			},
+			path: []string{"spec", "foo", "0"},
+			want: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"foo": []interface{}{"value"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			setPath(tt.obj, tt.path, "value")
+			g.Expect(tt.obj).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestWalkLeavesTreatsEmptyCollectionsAsLeaves(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := map[string]interface{}{
+		"empty_map":   map[string]interface{}{},
+		"empty_slice": []interface{}{},
+		"nested": map[string]interface{}{
+			"leaf": "value",
+		},
+	}
+
+	seen := map[string]interface{}{}
+	walkLeaves(obj, nil, func(path []string, value interface{}) {
+		seen[encodePath(path)] = value
+	})
+
+	g.Expect(seen).To(HaveKeyWithValue("empty_map", map[string]interface{}{}))
+	g.Expect(seen).To(HaveKeyWithValue("empty_slice", []interface{}{}))
+	g.Expect(seen).To(HaveKeyWithValue("nested.leaf", "value"))
+}
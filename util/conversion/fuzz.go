@@ -0,0 +1,298 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	fuzz "github.com/google/gofuzz"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+	"sigs.k8s.io/yaml"
+)
+
+// fuzzSeedEnvVar, when set, pins the base RNG seed FuzzTestFunc uses instead of a random one, so
+// a failing run can be reproduced exactly by re-running with the same value.
+const fuzzSeedEnvVar = "CAPI_FUZZ_SEED"
+
+// defaultFuzzIterations matches the iteration count FuzzTestFunc has always run; it stays the
+// default so existing callers see unchanged wall-clock in PR CI.
+const defaultFuzzIterations = 10000
+
+// FuzzTestFuncOptions controls how FuzzTestFunc exercises the spoke<->hub round trip.
+type FuzzTestFuncOptions struct {
+	// Iterations is the number of fuzz+round-trip iterations to run per direction.
+	// Defaults to 10000 when zero.
+	Iterations int
+
+	// Parallelism shards Iterations across this many goroutines, each with its own
+	// deterministically-derived RNG seed. Defaults to 1 (sequential) when zero.
+	Parallelism int
+
+	// FuzzerFuncs are merged after the defaults, metafuzzer.Funcs and KStatusFuzzerFuncs, which
+	// are always applied.
+	FuzzerFuncs []fuzzer.FuzzerFuncs
+}
+
+func (o FuzzTestFuncOptions) withDefaults() FuzzTestFuncOptions {
+	if o.Iterations == 0 {
+		o.Iterations = defaultFuzzIterations
+	}
+	if o.Parallelism == 0 {
+		o.Parallelism = 1
+	}
+	return o
+}
+
+// baseFuzzSeed picks the RNG seed every shard derives from: CAPI_FUZZ_SEED if set and parseable,
+// otherwise a random one (logged by FuzzTestFunc so a failure can be pinned down afterwards).
+func baseFuzzSeed() int64 {
+	if raw := os.Getenv(fuzzSeedEnvVar); raw != "" {
+		if seed, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return seed
+		}
+	}
+	return rand.Int63()
+}
+
+func getSeededFuzzer(scheme *runtime.Scheme, seed int64, funcs ...fuzzer.FuzzerFuncs) *fuzz.Fuzzer {
+	funcs = append([]fuzzer.FuzzerFuncs{metafuzzer.Funcs, KStatusFuzzerFuncs()}, funcs...)
+	return fuzzer.FuzzerFor(
+		fuzzer.MergeFuzzerFuncs(funcs...),
+		rand.NewSource(seed),
+		serializer.NewCodecFactory(scheme),
+	)
+}
+
+// FuzzTestFunc returns a new testing function to be used in tests to make sure conversions
+// between the Hub version of an object and an older version aren't lossy, using the default
+// options (see FuzzTestFuncOptions). Use FuzzTestFuncWithOptions to control iteration count,
+// parallelism or extra fuzzer funcs.
+func FuzzTestFunc(scheme *runtime.Scheme, hub conversion.Hub, dst conversion.Convertible, funcs ...fuzzer.FuzzerFuncs) func(*testing.T) {
+	return FuzzTestFuncWithOptions(scheme, hub, dst, FuzzTestFuncOptions{FuzzerFuncs: funcs})
+}
+
+// FuzzTestFuncWithOptions behaves like FuzzTestFunc, but lets the caller control iteration count
+// and parallelism via opts instead of always running the package default. On a round-trip
+// mismatch, it shrinks the failing input to the smallest subtree that still reproduces the diff
+// and prints it as YAML alongside the diff, so the failure can be checked in as a fixture.
+func FuzzTestFuncWithOptions(scheme *runtime.Scheme, hub conversion.Hub, dst conversion.Convertible, opts FuzzTestFuncOptions) func(*testing.T) {
+	opts = opts.withDefaults()
+	seed := baseFuzzSeed()
+
+	return func(t *testing.T) {
+		t.Logf("using %s=%d as the base fuzz seed; set this env var to reproduce a failure", fuzzSeedEnvVar, seed)
+
+		t.Run("spoke-hub-spoke", func(t *testing.T) {
+			runSharded(t, scheme, opts, seed, func(t *testing.T, fz *fuzz.Fuzzer) {
+				g := gomega.NewWithT(t)
+
+				spokeBefore := dst.DeepCopyObject().(conversion.Convertible)
+				fz.Fuzz(spokeBefore)
+
+				spokeAfter, err := spokeHubSpoke(hub, dst, spokeBefore)
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				if apiequality.Semantic.DeepEqual(spokeBefore, spokeAfter) {
+					return
+				}
+
+				minimal := shrink(spokeBefore, func(c interface{}) bool {
+					candidate := c.(conversion.Convertible)
+					after, err := spokeHubSpoke(hub, dst, candidate)
+					return err == nil && !apiequality.Semantic.DeepEqual(candidate, after)
+				})
+
+				minimalAfter, err := spokeHubSpoke(hub, dst, minimal.(conversion.Convertible))
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				reportFuzzFailure(t, "spoke-hub-spoke", minimal, minimalAfter)
+			})
+		})
+
+		t.Run("hub-spoke-hub", func(t *testing.T) {
+			runSharded(t, scheme, opts, seed, func(t *testing.T, fz *fuzz.Fuzzer) {
+				g := gomega.NewWithT(t)
+
+				hubBefore := hub.DeepCopyObject().(conversion.Hub)
+				fz.Fuzz(hubBefore)
+
+				hubAfter, err := hubSpokeHub(hub, dst, hubBefore)
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				if apiequality.Semantic.DeepEqual(hubBefore, hubAfter) {
+					return
+				}
+
+				minimal := shrink(hubBefore, func(c interface{}) bool {
+					candidate := c.(conversion.Hub)
+					after, err := hubSpokeHub(hub, dst, candidate)
+					return err == nil && !apiequality.Semantic.DeepEqual(candidate, after)
+				})
+
+				minimalAfter, err := hubSpokeHub(hub, dst, minimal.(conversion.Hub))
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+
+				reportFuzzFailure(t, "hub-spoke-hub", minimal, minimalAfter)
+			})
+		})
+	}
+}
+
+// runSharded splits opts.Iterations across opts.Parallelism goroutines and invokes run once per
+// iteration on a *testing.T scoped to that shard via t.Run, so a failure reports which shard and
+// iteration produced it. Each shard gets its own fuzzer seeded deterministically off baseSeed, so
+// a failure in shard N of a given base seed always reproduces the same way. Iterations that don't
+// divide evenly across shards are distributed one-per-shard starting from shard 0, so the total
+// number of iterations run always equals opts.Iterations exactly.
+func runSharded(t *testing.T, scheme *runtime.Scheme, opts FuzzTestFuncOptions, baseSeed int64, run func(t *testing.T, fz *fuzz.Fuzzer)) {
+	perShard := opts.Iterations / opts.Parallelism
+	remainder := opts.Iterations % opts.Parallelism
+
+	for shard := 0; shard < opts.Parallelism; shard++ {
+		shard := shard
+		iterations := perShard
+		if shard < remainder {
+			iterations++
+		}
+		if iterations == 0 {
+			continue
+		}
+
+		t.Run(fmt.Sprintf("shard-%d", shard), func(t *testing.T) {
+			if opts.Parallelism > 1 {
+				t.Parallel()
+			}
+
+			fz := getSeededFuzzer(scheme, baseSeed+int64(shard), opts.FuzzerFuncs...)
+			for i := 0; i < iterations; i++ {
+				run(t, fz)
+			}
+		})
+	}
+}
+
+// reportFuzzFailure prints the shrunk minimal reproducer, plus a diff between it and its own
+// round-tripped result, so a failure can be checked in as a small, focused fixture instead of a
+// diff over the full (potentially huge) originally-fuzzed object.
+func reportFuzzFailure(t *testing.T, direction string, minimal, minimalAfter interface{}) {
+	yamlOut, err := yaml.Marshal(minimal)
+	if err != nil {
+		yamlOut = []byte(fmt.Sprintf("<failed to marshal minimal reproducer to YAML: %v>", err))
+	}
+
+	t.Errorf("%s round trip is lossy\nminimal reproducer:\n%s\ndiff (-before +after):\n%s",
+		direction, string(yamlOut), cmp.Diff(minimal, minimalAfter))
+}
+
+// spokeHubSpoke converts a spoke object to the hub version and back, clearing the annotations
+// ConvertFrom may have added to avoid data loss in hub-spoke-hub round trips.
+func spokeHubSpoke(hub conversion.Hub, dst conversion.Convertible, spokeBefore conversion.Convertible) (conversion.Convertible, error) {
+	hubCopy := hub.DeepCopyObject().(conversion.Hub)
+	if err := spokeBefore.ConvertTo(hubCopy); err != nil {
+		return nil, err
+	}
+
+	spokeAfter := dst.DeepCopyObject().(conversion.Convertible)
+	if err := spokeAfter.ConvertFrom(hubCopy); err != nil {
+		return nil, err
+	}
+
+	metaAfter := spokeAfter.(metav1.Object)
+	delete(metaAfter.GetAnnotations(), DataAnnotation)
+	delete(metaAfter.GetAnnotations(), EncodingAnnotation)
+	delete(metaAfter.GetAnnotations(), DeltaDataAnnotation)
+	delete(metaAfter.GetAnnotations(), KStatusAnnotation)
+
+	return spokeAfter, nil
+}
+
+// hubSpokeHub converts a hub object to the spoke version and back.
+func hubSpokeHub(hub conversion.Hub, dst conversion.Convertible, hubBefore conversion.Hub) (conversion.Hub, error) {
+	dstCopy := dst.DeepCopyObject().(conversion.Convertible)
+	if err := dstCopy.ConvertFrom(hubBefore); err != nil {
+		return nil, err
+	}
+
+	hubAfter := hub.DeepCopyObject().(conversion.Hub)
+	if err := dstCopy.ConvertTo(hubAfter); err != nil {
+		return nil, err
+	}
+
+	return hubAfter, nil
+}
+
+// deepCopier is the common subset of conversion.Convertible and conversion.Hub that shrink needs:
+// both embed runtime.Object, which provides it.
+type deepCopier interface {
+	DeepCopyObject() runtime.Object
+}
+
+// shrink repeatedly zeroes top-level, then nested, fields of a copy of failing and re-runs
+// stillFails, keeping a zeroing only when the failure still reproduces. It returns the smallest
+// subtree of failing it found that still reproduces the original mismatch.
+func shrink(failing deepCopier, stillFails func(interface{}) bool) interface{} {
+	current := failing.DeepCopyObject()
+	shrinkValue(reflect.ValueOf(current).Elem(), func() bool { return stillFails(current) })
+	return current
+}
+
+// shrinkValue walks v's fields depth-first, trying to zero each one and keeping the zeroing if
+// recheck still reports a failure; it recurses into structs and pointers-to-structs so nested
+// fields get the same treatment.
+func shrinkValue(v reflect.Value, recheck func() bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			shrinkValue(v.Elem(), recheck)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+
+			original := reflect.New(field.Type()).Elem()
+			original.Set(field)
+
+			field.Set(reflect.Zero(field.Type()))
+			if recheck() {
+				// Zeroing this field still reproduces the failure: keep it zeroed and recurse no
+				// further into it, since there's nothing left to shrink underneath a zero value.
+				continue
+			}
+
+			// Zeroing hid the failure: restore the field and, if it's a nested struct/pointer,
+			// try shrinking inside it instead.
+			field.Set(original)
+			shrinkValue(field, recheck)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	fuzz "github.com/google/gofuzz"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/json"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+const (
+	// ReadyCondition is the kstatus condition type reported once an object has converged.
+	ReadyCondition = "Ready"
+	// ReconcilingCondition mirrors kstatus' Reconciling condition, set True while an object is
+	// still being actively worked towards its desired state.
+	ReconcilingCondition = "Reconciling"
+	// StalledCondition mirrors kstatus' Stalled condition, set True when reconciliation cannot
+	// make further progress without intervention.
+	StalledCondition = "Stalled"
+
+	// KStatusAnnotation stores the exact wire form of condition types UnmarshalData/MarshalData
+	// don't know about, so a hub -> spoke -> hub round trip doesn't drop them.
+	KStatusAnnotation = "cluster.x-k8s.io/conversion-kstatus-data"
+
+	// kstatusAggregateReason marks the Ready/Reconciling/Stalled entries ConvertConditionsToKStatus
+	// synthesizes on top of the 1:1-mapped conditions, so ConvertKStatusToConditions can tell them
+	// apart from a genuine condition a spoke happens to also call "Ready" (clusterv1.ReadyCondition
+	// is a real, commonly hand-set CAPI condition type) and only strip the ones it added.
+	kstatusAggregateReason = "KStatusAggregate"
+)
+
+// ConvertConditionsToKStatus maps a CAPI Conditions list onto a kstatus-conformant
+// []metav1.Condition, deriving aggregate entries from CAPI's Status+Severity pairs: any False
+// condition with ConditionSeverityError adds Stalled=True, otherwise any other False condition adds
+// Reconciling=True, and a Ready entry is always added, True when src has no False conditions and
+// False otherwise. kstatus conditions are keyed by Type, so at most one of each is ever emitted
+// even if several source conditions are False. observedGeneration is stamped
+// onto every emitted condition, per the kstatus convention of tracking which generation of the
+// object a condition was computed for.
+func ConvertConditionsToKStatus(src clusterv1.Conditions, observedGeneration int64, dst *[]metav1.Condition) {
+	out := make([]metav1.Condition, 0, len(src)+1)
+	ready := true
+	var stalled, reconciling *clusterv1.Condition
+
+	for i, c := range src {
+		status := metav1.ConditionUnknown
+		switch c.Status {
+		case corev1.ConditionTrue:
+			status = metav1.ConditionTrue
+		case corev1.ConditionFalse:
+			status = metav1.ConditionFalse
+		}
+
+		out = append(out, metav1.Condition{
+			Type:               string(c.Type),
+			Status:             status,
+			ObservedGeneration: observedGeneration,
+			Reason:             conditionReason(c),
+			Message:            c.Message,
+			LastTransitionTime: c.LastTransitionTime,
+		})
+
+		if c.Status == corev1.ConditionFalse {
+			ready = false
+			if c.Severity == clusterv1.ConditionSeverityError {
+				stalled = &src[i]
+			} else if reconciling == nil {
+				reconciling = &src[i]
+			}
+		}
+	}
+
+	// kstatus conditions are keyed by Type, so at most one Stalled and one Reconciling entry can
+	// be emitted regardless of how many source conditions are False: Stalled (the more severe of
+	// the two) wins if any error-severity False condition exists, otherwise Reconciling does if
+	// any other False condition exists.
+	if stalled != nil {
+		out = append(out, metav1.Condition{
+			Type:               StalledCondition,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: observedGeneration,
+			Reason:             kstatusAggregateReason,
+			Message:            stalled.Message,
+		})
+	} else if reconciling != nil {
+		out = append(out, metav1.Condition{
+			Type:               ReconcilingCondition,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: observedGeneration,
+			Reason:             kstatusAggregateReason,
+			Message:            reconciling.Message,
+		})
+	}
+
+	out = append(out, metav1.Condition{
+		Type:               ReadyCondition,
+		Status:             boolToConditionStatus(ready),
+		ObservedGeneration: observedGeneration,
+		Reason:             kstatusAggregateReason,
+	})
+
+	*dst = out
+}
+
+// ConvertKStatusToConditions is the reverse of ConvertConditionsToKStatus: it drops the
+// Ready/Reconciling/Stalled entries ConvertConditionsToKStatus synthesized (identified by
+// kstatusAggregateReason, not by type name, since CAPI conditions can legitimately be named
+// "Ready" too) and maps the remaining kstatus conditions back onto clusterv1.Conditions.
+func ConvertKStatusToConditions(src []metav1.Condition, dst *clusterv1.Conditions) {
+	out := make(clusterv1.Conditions, 0, len(src))
+
+	for _, c := range src {
+		if c.Reason == kstatusAggregateReason && (c.Type == ReadyCondition || c.Type == ReconcilingCondition || c.Type == StalledCondition) {
+			continue
+		}
+
+		status := corev1.ConditionUnknown
+		switch c.Status {
+		case metav1.ConditionTrue:
+			status = corev1.ConditionTrue
+		case metav1.ConditionFalse:
+			status = corev1.ConditionFalse
+		}
+
+		out = append(out, clusterv1.Condition{
+			Type:               clusterv1.ConditionType(c.Type),
+			Status:             status,
+			Severity:           conditionSeverity(c),
+			Reason:             c.Reason,
+			Message:            c.Message,
+			LastTransitionTime: c.LastTransitionTime,
+		})
+	}
+
+	*dst = out
+}
+
+// PreserveKStatusInAnnotation stashes the json-encoded src conditions under KStatusAnnotation on
+// dst, alongside (not instead of) the regular MarshalData annotation, so that condition types a
+// spoke doesn't understand survive a hub -> spoke -> hub round trip unmodified.
+func PreserveKStatusInAnnotation(src []metav1.Condition, dst metav1.Object) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	if dst.GetAnnotations() == nil {
+		dst.SetAnnotations(map[string]string{})
+	}
+	dst.GetAnnotations()[KStatusAnnotation] = string(data)
+	return nil
+}
+
+// RestoreKStatusFromAnnotation is the counterpart to PreserveKStatusInAnnotation: it reads back
+// the preserved conditions, if any, and removes the annotation from from.
+func RestoreKStatusFromAnnotation(from metav1.Object) ([]metav1.Condition, bool, error) {
+	data, ok := from.GetAnnotations()[KStatusAnnotation]
+	if !ok {
+		return nil, false, nil
+	}
+
+	var conditions []metav1.Condition
+	if err := json.Unmarshal([]byte(data), &conditions); err != nil {
+		return nil, false, err
+	}
+	delete(from.GetAnnotations(), KStatusAnnotation)
+	return conditions, true, nil
+}
+
+// KStatusFuzzerFuncs biases the fuzzer towards kstatus-conformant metav1.Condition values (a
+// non-empty Reason and a valid Status) so FuzzTestFunc's round-trip check exercises realistic
+// condition payloads instead of the zero-value-heavy output gofuzz would otherwise produce.
+func KStatusFuzzerFuncs() fuzzer.FuzzerFuncs {
+	return func(codecs serializer.CodecFactory) []interface{} {
+		return []interface{}{
+			func(c *metav1.Condition, fuzzer fuzz.Continue) {
+				fuzzer.FuzzNoCustom(c)
+				statuses := []metav1.ConditionStatus{metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionUnknown}
+				c.Status = statuses[fuzzer.Intn(len(statuses))]
+				if c.Reason == "" {
+					c.Reason = "FuzzedReason"
+				}
+			},
+		}
+	}
+}
+
+// conditionReason picks the Reason to report on the kstatus condition mapped from c. A non-empty
+// c.Reason is always passed through unchanged, regardless of status, so it can be recovered by
+// ConvertKStatusToConditions; kstatus requires Reason to be non-empty, so only a condition CAPI
+// left without one (CAPI doesn't enforce Reason being set) falls back to a generic placeholder.
+func conditionReason(c clusterv1.Condition) string {
+	if c.Reason != "" {
+		return string(c.Reason)
+	}
+	if c.Status == corev1.ConditionTrue {
+		return "Ready"
+	}
+	return "NoReason"
+}
+
+func conditionSeverity(c metav1.Condition) clusterv1.ConditionSeverity {
+	if c.Status == metav1.ConditionFalse {
+		return clusterv1.ConditionSeverityWarning
+	}
+	return clusterv1.ConditionSeverityNone
+}
+
+func boolToConditionStatus(b bool) metav1.ConditionStatus {
+	if b {
+		return metav1.ConditionTrue
+	}
+	return metav1.ConditionFalse
+}
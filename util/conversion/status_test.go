@@ -0,0 +1,108 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+func TestConvertConditionsToKStatusAggregatesOncePerType(t *testing.T) {
+	g := NewWithT(t)
+
+	src := clusterv1.Conditions{
+		{Type: "A", Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityWarning},
+		{Type: "B", Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityWarning},
+		{Type: "C", Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityError},
+	}
+
+	var out []metav1.Condition
+	ConvertConditionsToKStatus(src, 3, &out)
+
+	reconciling := conditionsOfType(out, ReconcilingCondition)
+	stalled := conditionsOfType(out, StalledCondition)
+	ready := conditionsOfType(out, ReadyCondition)
+
+	// kstatus conditions are keyed by Type: even though two source conditions are
+	// error-severity-free False and one is error-severity False, at most one Reconciling and one
+	// Stalled entry may ever be emitted.
+	g.Expect(reconciling).To(HaveLen(1))
+	g.Expect(stalled).To(HaveLen(1))
+	g.Expect(ready).To(HaveLen(1))
+	g.Expect(ready[0].Status).To(Equal(metav1.ConditionFalse))
+}
+
+func TestConvertConditionsToKStatusReconcilingOnlyWithoutErrorSeverity(t *testing.T) {
+	g := NewWithT(t)
+
+	src := clusterv1.Conditions{
+		{Type: "A", Status: corev1.ConditionFalse, Severity: clusterv1.ConditionSeverityWarning},
+	}
+
+	var out []metav1.Condition
+	ConvertConditionsToKStatus(src, 1, &out)
+
+	g.Expect(conditionsOfType(out, ReconcilingCondition)).To(HaveLen(1))
+	g.Expect(conditionsOfType(out, StalledCondition)).To(BeEmpty())
+}
+
+func TestConditionReasonPreservesRealReasonOnTrue(t *testing.T) {
+	g := NewWithT(t)
+
+	c := clusterv1.Condition{Status: corev1.ConditionTrue, Reason: "AlreadyConverged"}
+	g.Expect(conditionReason(c)).To(Equal("AlreadyConverged"))
+
+	c = clusterv1.Condition{Status: corev1.ConditionTrue}
+	g.Expect(conditionReason(c)).To(Equal("Ready"))
+
+	c = clusterv1.Condition{Status: corev1.ConditionFalse}
+	g.Expect(conditionReason(c)).To(Equal("NoReason"))
+
+	c = clusterv1.Condition{Status: corev1.ConditionFalse, Reason: "WaitingForX"}
+	g.Expect(conditionReason(c)).To(Equal("WaitingForX"))
+}
+
+func TestConvertKStatusToConditionsKeepsGenuineReadyCondition(t *testing.T) {
+	g := NewWithT(t)
+
+	src := []metav1.Condition{
+		// A genuine CAPI condition that happens to be named "Ready", not the aggregate
+		// ConvertConditionsToKStatus synthesizes.
+		{Type: ReadyCondition, Status: metav1.ConditionTrue, Reason: "AlreadyConverged"},
+		{Type: ReadyCondition, Status: metav1.ConditionTrue, Reason: kstatusAggregateReason},
+	}
+
+	var out clusterv1.Conditions
+	ConvertKStatusToConditions(src, &out)
+
+	g.Expect(out).To(HaveLen(1))
+	g.Expect(string(out[0].Reason)).To(Equal("AlreadyConverged"))
+}
+
+func conditionsOfType(conditions []metav1.Condition, t string) []metav1.Condition {
+	var out []metav1.Condition
+	for _, c := range conditions {
+		if c.Type == t {
+			out = append(out, c)
+		}
+	}
+	return out
+}